@@ -20,58 +20,144 @@ package checks
 import (
 	"fmt"
 	"math"
+)
+
+// floatClass is the result of classifying a float64 before subjecting it to
+// an ordering comparison. Bare `<`, `<=` and `==` against a NaN are always
+// false, which lets NaN silently slip past a naive boundary check (e.g.
+// `epsilon <= 0` is false for NaN). Dispatching through finite/finitePositive/
+// finiteNonNegative first guarantees the NaN (and Inf) branch is always taken
+// before any ordering comparison runs.
+type floatClass int
 
-	log "github.com/golang/glog"
+const (
+	floatOK floatClass = iota
+	floatNaN
+	floatInf
+	floatWrongSign
 )
 
+// normalizeZero rewrites -0.0 to +0.0 so that boundary comparisons (e.g.
+// lower == upper, or a threshold check at exactly zero) don't depend on which
+// signed zero a caller happened to construct or propagate through upstream
+// arithmetic.
+func normalizeZero(x float64) float64 {
+	if x == 0 {
+		return 0
+	}
+	return x
+}
+
+// finite classifies x as NaN, ±∞, or OK, normalizing -0.0 to +0.0 in the OK
+// case.
+func finite(x float64) (float64, floatClass) {
+	if math.IsNaN(x) {
+		return x, floatNaN
+	}
+	if math.IsInf(x, 0) {
+		return x, floatInf
+	}
+	return normalizeZero(x), floatOK
+}
+
+// finitePositive classifies x as NaN, ±∞, nonpositive, or OK (finite and
+// strictly positive), normalizing -0.0 to +0.0 before the sign check so that
+// negative zero is treated as nonpositive rather than as a positive value.
+func finitePositive(x float64) (float64, floatClass) {
+	x, class := finite(x)
+	if class != floatOK {
+		return x, class
+	}
+	if x <= 0 {
+		return x, floatWrongSign
+	}
+	return x, floatOK
+}
+
+// finiteNonNegative classifies x as NaN, ±∞, negative, or OK (finite and
+// nonnegative), normalizing -0.0 to +0.0 before the sign check so that
+// negative zero is treated as nonnegative.
+func finiteNonNegative(x float64) (float64, floatClass) {
+	x, class := finite(x)
+	if class != floatOK {
+		return x, class
+	}
+	if x < 0 {
+		return x, floatWrongSign
+	}
+	return x, floatOK
+}
+
+// signKind maps a floatClass produced by finite/finitePositive/finiteNonNegative
+// to the ErrorKind it should be reported as, given what the caller expects the
+// wrong-sign case to mean for this particular parameter.
+func signKind(class floatClass, wrongSignKind ErrorKind) ErrorKind {
+	switch class {
+	case floatNaN:
+		return KindNaN
+	case floatInf:
+		return KindInf
+	default:
+		return wrongSignKind
+	}
+}
+
 // CheckEpsilonVeryStrict returns an error if ε is +∞ or less than 2⁻⁵⁰.
 func CheckEpsilonVeryStrict(label string, epsilon float64) error {
-	if epsilon < math.Exp2(-50.0) || math.IsInf(epsilon, 0) || math.IsNaN(epsilon) {
-		return fmt.Errorf("%s: Epsilon is %f, should be at least 2^-50 (and cannot be infinity or NaN)", label, epsilon)
+	epsilon, class := finite(epsilon)
+	if class != floatOK || epsilon < math.Exp2(-50.0) {
+		return &ValidationError{Label: label, Param: "Epsilon", Kind: signKind(class, KindOutOfRange), Value: epsilon,
+			Constraint: "should be at least 2^-50 (and cannot be infinity or NaN)"}
 	}
 	return nil
 }
 
 // CheckEpsilonStrict returns an error if ε is nonpositive or +∞.
 func CheckEpsilonStrict(label string, epsilon float64) error {
-	if epsilon <= 0 || math.IsInf(epsilon, 0) || math.IsNaN(epsilon) {
-		return fmt.Errorf("%s: Epsilon is %f, should be strictly positive (and cannot be infinity or NaN)", label, epsilon)
+	epsilon, class := finitePositive(epsilon)
+	if class != floatOK {
+		return &ValidationError{Label: label, Param: "Epsilon", Kind: signKind(class, KindNonPositive), Value: epsilon,
+			Constraint: "should be strictly positive (and cannot be infinity or NaN)"}
 	}
 	return nil
 }
 
 // CheckEpsilon returns an error if ε is strictly negative or +∞.
 func CheckEpsilon(label string, epsilon float64) error {
-	if epsilon < 0 || math.IsInf(epsilon, 0) || math.IsNaN(epsilon) {
-		return fmt.Errorf("%s: Epsilon is %f, should be nonnegative (and cannot be infinity or NaN)", label, epsilon)
+	epsilon, class := finiteNonNegative(epsilon)
+	if class != floatOK {
+		return &ValidationError{Label: label, Param: "Epsilon", Kind: signKind(class, KindOutOfRange), Value: epsilon,
+			Constraint: "should be nonnegative (and cannot be infinity or NaN)"}
 	}
 	return nil
 }
 
 // CheckDelta returns an error if δ is negative or greater than or equal to 1.
 func CheckDelta(label string, delta float64) error {
-	if math.IsNaN(delta) {
-		return fmt.Errorf("%s: Delta is %e, cannot be NaN", label, delta)
+	delta, class := finite(delta)
+	if class == floatNaN {
+		return &ValidationError{Label: label, Param: "Delta", Kind: KindNaN, Value: delta, Constraint: "cannot be NaN"}
 	}
 	if delta < 0 {
-		return fmt.Errorf("%s: Delta is %e, cannot be negative", label, delta)
+		return &ValidationError{Label: label, Param: "Delta", Kind: KindOutOfRange, Value: delta, Constraint: "cannot be negative"}
 	}
 	if delta >= 1 {
-		return fmt.Errorf("%s: Delta is %e, should be strictly less than 1", label, delta)
+		return &ValidationError{Label: label, Param: "Delta", Kind: KindOutOfRange, Value: delta, Constraint: "should be strictly less than 1"}
 	}
 	return nil
 }
 
 // CheckDeltaStrict returns an error if δ is nonpositive or greater than or equal to 1.
 func CheckDeltaStrict(label string, delta float64) error {
-	if math.IsNaN(delta) {
-		return fmt.Errorf("%s: Delta is %e, cannot be NaN", label, delta)
+	delta, class := finite(delta)
+	if class == floatNaN {
+		return &ValidationError{Label: label, Param: "Delta", Kind: KindNaN, Value: delta, Constraint: "cannot be NaN"}
 	}
 	if delta <= 0 {
-		return fmt.Errorf("%s: Delta is %e, should be strictly positive", label, delta)
+		return &ValidationError{Label: label, Param: "Delta", Kind: KindNonPositive, Value: delta, Constraint: "should be strictly positive"}
 	}
 	if delta >= 1 {
-		return fmt.Errorf("%s: Delta is %e, should be strictly less than 1", label, delta)
+		return &ValidationError{Label: label, Param: "Delta", Kind: KindOutOfRange, Value: delta, Constraint: "should be strictly less than 1"}
 	}
 	return nil
 }
@@ -79,7 +165,7 @@ func CheckDeltaStrict(label string, delta float64) error {
 // CheckNoDelta returns an error if δ is non-zero.
 func CheckNoDelta(label string, delta float64) error {
 	if delta != 0 {
-		return fmt.Errorf("%s: Delta is %e, should be 0", label, delta)
+		return &ValidationError{Label: label, Param: "Delta", Kind: KindOutOfRange, Value: delta, Constraint: "should be 0"}
 	}
 	return nil
 }
@@ -87,15 +173,17 @@ func CheckNoDelta(label string, delta float64) error {
 // CheckL0Sensitivity returns an error if l0Sensitivity is nonpositive.
 func CheckL0Sensitivity(label string, l0Sensitivity int64) error {
 	if l0Sensitivity <= 0 {
-		return fmt.Errorf("%s: L0Sensitivity is %d, should be strictly positive", label, l0Sensitivity)
+		return &ValidationError{Label: label, Param: "L0Sensitivity", Kind: KindNonPositive, Value: l0Sensitivity, Constraint: "should be strictly positive"}
 	}
 	return nil
 }
 
 // CheckLInfSensitivity returns an error if lInfSensitivity is nonpositive or +∞.
 func CheckLInfSensitivity(label string, lInfSensitivity float64) error {
-	if lInfSensitivity <= 0 || math.IsInf(lInfSensitivity, 0) || math.IsNaN(lInfSensitivity) {
-		return fmt.Errorf("%s: LInfSensitivity is %f, should be strictly positive (and cannot be infinity or NaN)", label, lInfSensitivity)
+	lInfSensitivity, class := finitePositive(lInfSensitivity)
+	if class != floatOK {
+		return &ValidationError{Label: label, Param: "LInfSensitivity", Kind: signKind(class, KindNonPositive), Value: lInfSensitivity,
+			Constraint: "should be strictly positive (and cannot be infinity or NaN)"}
 	}
 	return nil
 }
@@ -103,13 +191,16 @@ func CheckLInfSensitivity(label string, lInfSensitivity float64) error {
 // CheckBoundsInt64 returns an error if lower is larger than upper, and ensures it won't lead to sensitivity overflow.
 func CheckBoundsInt64(label string, lower, upper int64) error {
 	if lower == math.MinInt64 || upper == math.MinInt64 {
-		return fmt.Errorf("%s: lower (%d) and upper (%d) must be strictly larger than math.MinInt64 to avoid sensitivity overflow", label, lower, upper)
+		return &ValidationError{Label: label, Param: "lower/upper", Kind: KindOverflowRisk, Value: [2]int64{lower, upper},
+			Constraint: "lower and upper must be strictly larger than math.MinInt64 to avoid sensitivity overflow"}
 	}
 	if lower > upper {
-		return fmt.Errorf("%s: Upper (%d) should be larger than Lower (%d)", label, upper, lower)
+		return &ValidationError{Label: label, Param: "lower/upper", Kind: KindBoundsInverted, Value: [2]int64{lower, upper},
+			Constraint: fmt.Sprintf("upper (%d) should be larger than lower (%d)", upper, lower)}
 	}
 	if lower == upper {
-		log.Warningf("Lower bound is equal to upper bound: all added elements will be clamped to %d", upper)
+		emitWarning(ValidationWarning{Param: "lower/upper", Label: label,
+			Message: fmt.Sprintf("Lower bound is equal to upper bound: all added elements will be clamped to %d", upper)})
 	}
 	return nil
 }
@@ -118,69 +209,81 @@ func CheckBoundsInt64(label string, lower, upper int64) error {
 // This is used when noise is unrecognised.
 func CheckBoundsInt64IgnoreOverflows(label string, lower, upper int64) error {
 	if lower > upper {
-		return fmt.Errorf("%s: Upper (%d) should be larger than Lower (%d)", label, upper, lower)
+		return &ValidationError{Label: label, Param: "lower/upper", Kind: KindBoundsInverted, Value: [2]int64{lower, upper},
+			Constraint: fmt.Sprintf("upper (%d) should be larger than lower (%d)", upper, lower)}
 	}
 	if lower == upper {
-		log.Warningf("Lower bound is equal to upper bound: all added elements will be clamped to %d", upper)
+		emitWarning(ValidationWarning{Param: "lower/upper", Label: label,
+			Message: fmt.Sprintf("Lower bound is equal to upper bound: all added elements will be clamped to %d", upper)})
 	}
 	return nil
 }
 
 // CheckBoundsFloat64 returns an error if lower is larger than upper, or if either parameter is ±∞.
 func CheckBoundsFloat64(label string, lower, upper float64) error {
-	if math.IsNaN(lower) {
-		return fmt.Errorf("%s: lower can't be NaN", label)
+	lower, lowerClass := finite(lower)
+	if lowerClass == floatNaN {
+		return &ValidationError{Label: label, Param: "lower", Kind: KindNaN, Value: lower, Constraint: "can't be NaN"}
 	}
-	if math.IsNaN(upper) {
-		return fmt.Errorf("%s: upper can't be NaN", label)
+	upper, upperClass := finite(upper)
+	if upperClass == floatNaN {
+		return &ValidationError{Label: label, Param: "upper", Kind: KindNaN, Value: upper, Constraint: "can't be NaN"}
 	}
-	if math.IsInf(lower, 0) {
-		return fmt.Errorf("%s: lower can't be infinity", label)
+	if lowerClass == floatInf {
+		return &ValidationError{Label: label, Param: "lower", Kind: KindInf, Value: lower, Constraint: "can't be infinity"}
 	}
-	if math.IsInf(upper, 0) {
-		return fmt.Errorf("%s: upper can't be infinity", label)
+	if upperClass == floatInf {
+		return &ValidationError{Label: label, Param: "upper", Kind: KindInf, Value: upper, Constraint: "can't be infinity"}
 	}
 	if lower > upper {
-		return fmt.Errorf("%s: Upper (%f) should be larger than Lower (%f)", label, upper, lower)
+		return &ValidationError{Label: label, Param: "lower/upper", Kind: KindBoundsInverted, Value: [2]float64{lower, upper},
+			Constraint: fmt.Sprintf("upper (%f) should be larger than lower (%f)", upper, lower)}
 	}
 	if lower == upper {
-		log.Warningf("Lower bound is equal to upper bound: all added elements will be clamped to %f", upper)
+		emitWarning(ValidationWarning{Param: "lower/upper", Label: label,
+			Message: fmt.Sprintf("Lower bound is equal to upper bound: all added elements will be clamped to %f", upper)})
 	}
 	return nil
 }
 
 // CheckBoundsFloat64IgnoreOverflows returns an error if lower is larger than upper but accepts either parameter being ±∞.
 func CheckBoundsFloat64IgnoreOverflows(label string, lower, upper float64) error {
-	if math.IsNaN(lower) {
-		return fmt.Errorf("%s: lower can't be NaN", label)
+	lower, lowerClass := finite(lower)
+	if lowerClass == floatNaN {
+		return &ValidationError{Label: label, Param: "lower", Kind: KindNaN, Value: lower, Constraint: "can't be NaN"}
 	}
-	if math.IsNaN(upper) {
-		return fmt.Errorf("%s: upper can't be NaN", label)
+	upper, upperClass := finite(upper)
+	if upperClass == floatNaN {
+		return &ValidationError{Label: label, Param: "upper", Kind: KindNaN, Value: upper, Constraint: "can't be NaN"}
 	}
 	if lower > upper {
-		return fmt.Errorf("%s: Upper (%f) should be larger than Lower (%f)", label, upper, lower)
+		return &ValidationError{Label: label, Param: "lower/upper", Kind: KindBoundsInverted, Value: [2]float64{lower, upper},
+			Constraint: fmt.Sprintf("upper (%f) should be larger than lower (%f)", upper, lower)}
 	}
 	if lower == upper {
-		log.Warningf("Lower bound is equal to upper bound: all added elements will be clamped to %f", upper)
+		emitWarning(ValidationWarning{Param: "lower/upper", Label: label,
+			Message: fmt.Sprintf("Lower bound is equal to upper bound: all added elements will be clamped to %f", upper)})
 	}
 	return nil
 }
 
 // CheckBoundsFloat64AsInt64 returns an error if lower is larger are NaN, or if either parameter overflow after conversion to int64.
 func CheckBoundsFloat64AsInt64(label string, lower, upper float64) error {
-	if math.IsNaN(lower) {
-		return fmt.Errorf("%s: Lower must not be NaN", label)
+	lower, lowerClass := finite(lower)
+	if lowerClass == floatNaN {
+		return &ValidationError{Label: label, Param: "Lower", Kind: KindNaN, Value: lower, Constraint: "must not be NaN"}
 	}
-	if math.IsNaN(upper) {
-		return fmt.Errorf("%s: Upper must not be NaN", label)
+	upper, upperClass := finite(upper)
+	if upperClass == floatNaN {
+		return &ValidationError{Label: label, Param: "Upper", Kind: KindNaN, Value: upper, Constraint: "must not be NaN"}
 	}
 	maxInt := float64(math.MaxInt64)
 	minInt := float64(math.MinInt64)
 	if lower < minInt || lower > maxInt {
-		return fmt.Errorf("%s: Lower should be within MinInt64 and MaxInt64 bounds, got %f", label, lower)
+		return &ValidationError{Label: label, Param: "Lower", Kind: KindOutOfRange, Value: lower, Constraint: "should be within MinInt64 and MaxInt64 bounds"}
 	}
 	if upper < minInt || upper > maxInt {
-		return fmt.Errorf("%s: Upper should be within MinInt64 and MaxInt64 bounds, got %f", label, upper)
+		return &ValidationError{Label: label, Param: "Upper", Kind: KindOutOfRange, Value: upper, Constraint: "should be within MinInt64 and MaxInt64 bounds"}
 	}
 	return CheckBoundsInt64(label, int64(lower), int64(upper))
 }
@@ -188,31 +291,44 @@ func CheckBoundsFloat64AsInt64(label string, lower, upper float64) error {
 // CheckMaxPartitionsContributed returns an error if maxPartitionsContributed is nonpositive.
 func CheckMaxPartitionsContributed(label string, maxPartitionsContributed int64) error {
 	if maxPartitionsContributed < 0 {
-		return fmt.Errorf("%s: MaxPartitionsContributed is %d, should not be negative", label, maxPartitionsContributed)
+		return &ValidationError{Label: label, Param: "MaxPartitionsContributed", Kind: KindOutOfRange, Value: maxPartitionsContributed,
+			Constraint: "should not be negative"}
 	}
 	return nil
 }
 
 // CheckConfidenceLevel returns an error if the supplied confidence level is not between 0 and 1.
 func CheckConfidenceLevel(label string, confidenceLevel float64) error {
-	if confidenceLevel < 0 || confidenceLevel > 1 || math.IsNaN(confidenceLevel) || math.IsInf(confidenceLevel, 0) {
-		return fmt.Errorf("%s: confidenceLevel is %f, should be between 0 and 1 (and cannot be NaN or Infinity)", label, confidenceLevel)
+	confidenceLevel, class := finite(confidenceLevel)
+	if class != floatOK || confidenceLevel < 0 || confidenceLevel > 1 {
+		return &ValidationError{Label: label, Param: "confidenceLevel", Kind: signKind(class, KindOutOfRange), Value: confidenceLevel,
+			Constraint: "should be between 0 and 1 (and cannot be NaN or Infinity)"}
 	}
 	return nil
 }
 
 // CheckAlpha returns an error if the supplied alpha is not between 0 and 1.
 func CheckAlpha(label string, alpha float64) error {
-	if alpha <= 0 || alpha >= 1 || math.IsNaN(alpha) || math.IsInf(alpha, 0) {
-		return fmt.Errorf("%s: alpha is %f, should be strictly between 0 and 1 (and cannot be NaN or Infinity)", label, alpha)
+	alpha, class := finite(alpha)
+	if class != floatOK || alpha <= 0 || alpha >= 1 {
+		return &ValidationError{Label: label, Param: "alpha", Kind: signKind(class, KindOutOfRange), Value: alpha,
+			Constraint: "should be strictly between 0 and 1 (and cannot be NaN or Infinity)"}
 	}
 	return nil
 }
 
-// CheckBoundsNotEqual returns an error if lower and upper bounds are equal.
+// CheckBoundsNotEqual returns an error if lower and upper bounds are equal, or
+// if either is NaN.
 func CheckBoundsNotEqual(label string, lower, upper float64) error {
+	lower, lowerClass := finite(lower)
+	upper, upperClass := finite(upper)
+	if lowerClass == floatNaN || upperClass == floatNaN {
+		return &ValidationError{Label: label, Param: "lower/upper", Kind: KindNaN, Value: [2]float64{lower, upper},
+			Constraint: "lower and upper cannot be NaN"}
+	}
 	if lower == upper {
-		return fmt.Errorf("%s: lower and upper are both %f, they should not be equal", label, lower)
+		return &ValidationError{Label: label, Param: "lower/upper", Kind: KindOutOfRange, Value: lower,
+			Constraint: "lower and upper should not be equal"}
 	}
 	return nil
 }
@@ -220,7 +336,7 @@ func CheckBoundsNotEqual(label string, lower, upper float64) error {
 // CheckTreeHeight returns an error if treeHeight is less than 1.
 func CheckTreeHeight(label string, treeHeight int) error {
 	if treeHeight < 1 {
-		return fmt.Errorf("%s: treeHeight is %d, should be at least 1", label, treeHeight)
+		return &ValidationError{Label: label, Param: "treeHeight", Kind: KindOutOfRange, Value: treeHeight, Constraint: "should be at least 1"}
 	}
 	return nil
 }
@@ -228,7 +344,189 @@ func CheckTreeHeight(label string, treeHeight int) error {
 // CheckBranchingFactor returns an error if branchingFactor is less than 2.
 func CheckBranchingFactor(label string, branchingFactor int) error {
 	if branchingFactor < 2 {
-		return fmt.Errorf("%s: branchingFactor is %d, should be at least 2", label, branchingFactor)
+		return &ValidationError{Label: label, Param: "branchingFactor", Kind: KindOutOfRange, Value: branchingFactor, Constraint: "should be at least 2"}
+	}
+	return nil
+}
+
+// Step describes a single mechanism invocation to be validated as part of a
+// sequence of releases by CheckComposedBudget. Mechanism is a free-form hint
+// (e.g. "laplace", "gaussian") carried through for error reporting; it does
+// not affect the composition math.
+type Step struct {
+	Epsilon   float64
+	Delta     float64
+	Mechanism string
+}
+
+// advancedCompositionEpsilon computes the advanced-composition bound on ε for
+// k adaptively chosen mechanisms each satisfying (epsilon, ·)-DP, allowing an
+// additional deltaPrime slack on δ. See Dwork & Roth, "The Algorithmic
+// Foundations of Differential Privacy", Theorem 3.20.
+//
+// CheckComposedBudget calls this with epsilon set to the largest ε_i seen so
+// far across a sequence of (possibly distinct) step budgets rather than a
+// single shared ε. This remains a sound, if conservative, bound: a mechanism
+// that is ε_i-DP with ε_i <= epsilon is, by definition, also epsilon-DP (the
+// DP guarantee only weakens as ε grows), so a heterogeneous prefix can always
+// be treated as k steps that are each (epsilon, ·)-DP for the purposes of
+// Theorem 3.20. The bound never under-counts privacy loss; it is tight only
+// when every step's ε equals the prefix maximum, and can be loose otherwise.
+// See also Kairouz, Oh & Viswanath, "The Composition Theorem for Differential
+// Privacy" (2015), which gives tighter bounds for the heterogeneous case that
+// this function does not attempt to reproduce.
+func advancedCompositionEpsilon(k int, epsilon, deltaPrime float64) float64 {
+	if deltaPrime <= 0 {
+		return math.Inf(1)
+	}
+	return math.Sqrt(2*float64(k)*math.Log(1/deltaPrime))*epsilon + float64(k)*epsilon*(math.Exp(epsilon)-1)
+}
+
+// CheckComposedBudget returns an error if the sequence of steps, composed in
+// order, can exceed the declared global (totalEpsilon, totalDelta) budget.
+// For every prefix of steps it computes both the basic sequential-composition
+// bound (sum of ε_i, sum of δ_i) and the advanced-composition bound (using the
+// largest ε seen so far and a δ' slack carved out of the remaining δ budget),
+// and checks the tighter of the two. The error identifies the first step, if
+// any, that pushes the prefix over budget.
+func CheckComposedBudget(label string, steps []Step, totalEpsilon, totalDelta float64) error {
+	if err := CheckEpsilon(label, totalEpsilon); err != nil {
+		return fmt.Errorf("invalid total epsilon budget: %w", err)
+	}
+	if err := CheckDelta(label, totalDelta); err != nil {
+		return fmt.Errorf("invalid total delta budget: %w", err)
+	}
+
+	var seqEpsilon, seqDelta, maxEpsilon float64
+	for i, step := range steps {
+		if err := CheckEpsilon(label, step.Epsilon); err != nil {
+			return fmt.Errorf("step %d (%s): invalid epsilon: %w", i, step.Mechanism, err)
+		}
+		if err := CheckDelta(label, step.Delta); err != nil {
+			return fmt.Errorf("step %d (%s): invalid delta: %w", i, step.Mechanism, err)
+		}
+
+		seqEpsilon += step.Epsilon
+		seqDelta += step.Delta
+		if step.Epsilon > maxEpsilon {
+			maxEpsilon = step.Epsilon
+		}
+
+		deltaPrime := totalDelta - seqDelta
+		advEpsilon := advancedCompositionEpsilon(i+1, maxEpsilon, deltaPrime)
+		boundEpsilon := math.Min(seqEpsilon, advEpsilon)
+
+		if boundEpsilon > totalEpsilon || seqDelta > totalDelta {
+			return &ValidationError{Label: label, Param: fmt.Sprintf("steps[%d]", i), Kind: KindOutOfRange, Value: step,
+				Constraint: fmt.Sprintf("step %d (%s) exceeds budget: after %d steps, composed epsilon is %f (budget %f), composed delta is %e (budget %e)",
+					i, step.Mechanism, i+1, boundEpsilon, totalEpsilon, seqDelta, totalDelta)}
+		}
+	}
+	return nil
+}
+
+// CheckSubsampledAmplification computes the (ε', δ') privacy parameters
+// obtained by applying an (epsilon, delta)-DP mechanism to a uniform random
+// sample of the input taken at samplingRate, via the standard amplification
+// bounds ε' = log(1 + q(e^ε − 1)), δ' = qδ. It returns an error if
+// samplingRate is not in (0, 1], or if epsilon or delta are themselves
+// invalid.
+func CheckSubsampledAmplification(label string, samplingRate, epsilon, delta float64) (float64, float64, error) {
+	samplingRate, class := finite(samplingRate)
+	if class != floatOK || samplingRate <= 0 || samplingRate > 1 {
+		return 0, 0, &ValidationError{Label: label, Param: "samplingRate", Kind: signKind(class, KindOutOfRange), Value: samplingRate,
+			Constraint: "should be in (0, 1]"}
+	}
+	if err := CheckEpsilon(label, epsilon); err != nil {
+		return 0, 0, err
+	}
+	if err := CheckDelta(label, delta); err != nil {
+		return 0, 0, err
+	}
+
+	epsilonPrime := math.Log(1 + samplingRate*(math.Exp(epsilon)-1))
+	deltaPrime := samplingRate * delta
+	return epsilonPrime, deltaPrime, nil
+}
+
+// CheckRDPOrder returns an error if alpha, the Rényi divergence order of an
+// RDP mechanism, is not strictly greater than 1 or is not finite.
+func CheckRDPOrder(label string, alpha float64) error {
+	alpha, class := finite(alpha)
+	if class != floatOK || alpha <= 1 {
+		return &ValidationError{Label: label, Param: "alpha", Kind: signKind(class, KindOutOfRange), Value: alpha,
+			Constraint: "should be finite and strictly greater than 1"}
+	}
+	return nil
+}
+
+// CheckRDPEpsilon returns an error if alpha is an invalid RDP order, or if
+// epsilon, the ε_RDP bound at that order, is negative or not finite.
+func CheckRDPEpsilon(label string, alpha, epsilon float64) error {
+	if err := CheckRDPOrder(label, alpha); err != nil {
+		return err
+	}
+	epsilon, class := finiteNonNegative(epsilon)
+	if class != floatOK {
+		return &ValidationError{Label: label, Param: "epsilon", Kind: signKind(class, KindOutOfRange), Value: epsilon,
+			Constraint: fmt.Sprintf("should be nonnegative and finite at order alpha=%f", alpha)}
+	}
+	return nil
+}
+
+// CheckZCDPRho returns an error if rho, the ρ parameter of a zero-concentrated
+// DP mechanism, is negative or not finite.
+func CheckZCDPRho(label string, rho float64) error {
+	rho, class := finiteNonNegative(rho)
+	if class != floatOK {
+		return &ValidationError{Label: label, Param: "rho", Kind: signKind(class, KindOutOfRange), Value: rho,
+			Constraint: "should be nonnegative and finite"}
+	}
+	return nil
+}
+
+// CheckGaussianSigma returns an error if sigma, the standard deviation of
+// Gaussian noise, or l2Sensitivity, the ℓ2 sensitivity it is calibrated
+// against, are nonpositive or not finite.
+func CheckGaussianSigma(label string, sigma, l2Sensitivity float64) error {
+	sigma, sigmaClass := finitePositive(sigma)
+	if sigmaClass != floatOK {
+		return &ValidationError{Label: label, Param: "sigma", Kind: signKind(sigmaClass, KindNonPositive), Value: sigma,
+			Constraint: "should be strictly positive and finite"}
+	}
+	l2Sensitivity, l2Class := finitePositive(l2Sensitivity)
+	if l2Class != floatOK {
+		return &ValidationError{Label: label, Param: "l2Sensitivity", Kind: signKind(l2Class, KindNonPositive), Value: l2Sensitivity,
+			Constraint: "should be strictly positive and finite"}
+	}
+	return nil
+}
+
+// CheckRDPToApproxDP returns an error if converting an (alpha, epsilonRDP)-RDP
+// guarantee to (ε, targetDelta)-DP via ε = epsilonRDP + log(1/targetDelta)/(alpha-1)
+// is not possible, or if the resulting ε exceeds epsilonCap, at which point
+// the bound is considered too loose to be useful to the caller.
+func CheckRDPToApproxDP(label string, alpha, epsilonRDP, targetDelta, epsilonCap float64) error {
+	if err := CheckRDPEpsilon(label, alpha, epsilonRDP); err != nil {
+		return err
+	}
+	if err := CheckDeltaStrict(label, targetDelta); err != nil {
+		return fmt.Errorf("invalid target delta: %w", err)
+	}
+	epsilonCap, class := finitePositive(epsilonCap)
+	if class != floatOK {
+		return &ValidationError{Label: label, Param: "epsilonCap", Kind: signKind(class, KindNonPositive), Value: epsilonCap,
+			Constraint: "should be strictly positive"}
+	}
+
+	epsilonDP := epsilonRDP + math.Log(1/targetDelta)/(alpha-1)
+	if _, class := finite(epsilonDP); class != floatOK {
+		return &ValidationError{Label: label, Param: "epsilonDP", Kind: signKind(class, KindOutOfRange), Value: epsilonDP,
+			Constraint: fmt.Sprintf("RDP-to-approxDP conversion produced a non-finite epsilon (alpha=%f, epsilonRDP=%f, targetDelta=%e)", alpha, epsilonRDP, targetDelta)}
+	}
+	if epsilonDP > epsilonCap {
+		return &ValidationError{Label: label, Param: "epsilonDP", Kind: KindOutOfRange, Value: epsilonDP,
+			Constraint: fmt.Sprintf("RDP-to-approxDP conversion yields epsilon=%f at alpha=%f, which exceeds the cap of %f and is too loose to be useful; try a larger alpha", epsilonDP, alpha, epsilonCap)}
 	}
 	return nil
 }
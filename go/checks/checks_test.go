@@ -0,0 +1,303 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package checks
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// negZero is -0.0, constructed so the compiler can't fold it into +0.0.
+var negZero = math.Copysign(0, -1)
+
+func TestCheckEpsilonVeryStrictEdgeCases(t *testing.T) {
+	threshold := math.Exp2(-50)
+	justBelow := math.Nextafter(threshold, 0)
+	justAbove := math.Nextafter(threshold, 1)
+
+	for _, tc := range []struct {
+		desc    string
+		epsilon float64
+		wantErr bool
+	}{
+		{"threshold itself is allowed", threshold, false},
+		{"one ULP below threshold fails", justBelow, true},
+		{"one ULP above threshold passes", justAbove, false},
+		{"subnormal epsilon fails", math.SmallestNonzeroFloat64, true},
+		{"signed zero fails", negZero, true},
+		{"NaN fails", math.NaN(), true},
+		{"+Inf fails", math.Inf(1), true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := CheckEpsilonVeryStrict("test", tc.epsilon)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CheckEpsilonVeryStrict(%v) returned err=%v, wantErr=%v", tc.epsilon, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckEpsilonStrictSignedZero(t *testing.T) {
+	if err := CheckEpsilonStrict("test", negZero); err == nil {
+		t.Error("CheckEpsilonStrict(-0.0) = nil, want error: negative zero is not strictly positive")
+	}
+	if err := CheckEpsilonStrict("test", math.SmallestNonzeroFloat64); err != nil {
+		t.Errorf("CheckEpsilonStrict(SmallestNonzeroFloat64) = %v, want nil", err)
+	}
+	if err := CheckEpsilonStrict("test", math.NaN()); err == nil {
+		t.Error("CheckEpsilonStrict(NaN) = nil, want error")
+	}
+}
+
+func TestCheckEpsilonAcceptsSignedZero(t *testing.T) {
+	if err := CheckEpsilon("test", negZero); err != nil {
+		t.Errorf("CheckEpsilon(-0.0) = %v, want nil: negative zero is nonnegative", err)
+	}
+	if err := CheckEpsilon("test", math.NaN()); err == nil {
+		t.Error("CheckEpsilon(NaN) = nil, want error")
+	}
+}
+
+func TestCheckDeltaEdgeCases(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		delta   float64
+		wantErr bool
+	}{
+		{"signed zero is allowed", negZero, false},
+		{"subnormal delta is allowed", math.SmallestNonzeroFloat64, false},
+		{"NaN fails", math.NaN(), true},
+		{"negative fails", -math.SmallestNonzeroFloat64, true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := CheckDelta("test", tc.delta)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CheckDelta(%v) returned err=%v, wantErr=%v", tc.delta, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckDeltaStrictRejectsSignedZero(t *testing.T) {
+	if err := CheckDeltaStrict("test", negZero); err == nil {
+		t.Error("CheckDeltaStrict(-0.0) = nil, want error: negative zero is not strictly positive")
+	}
+	if err := CheckDeltaStrict("test", math.SmallestNonzeroFloat64); err != nil {
+		t.Errorf("CheckDeltaStrict(SmallestNonzeroFloat64) = %v, want nil", err)
+	}
+}
+
+func TestCheckLInfSensitivitySignedZero(t *testing.T) {
+	if err := CheckLInfSensitivity("test", negZero); err == nil {
+		t.Error("CheckLInfSensitivity(-0.0) = nil, want error: negative zero is not strictly positive")
+	}
+}
+
+func TestCheckBoundsFloat64SignedZero(t *testing.T) {
+	// lower = -0.0 normalizes to +0.0, equal to upper = +0.0, so this is a
+	// degenerate-but-valid bound (a warning, not an error).
+	if err := CheckBoundsFloat64("test", negZero, 0); err != nil {
+		t.Errorf("CheckBoundsFloat64(-0.0, 0.0) = %v, want nil", err)
+	}
+	if err := CheckBoundsFloat64("test", math.NaN(), 1); err == nil {
+		t.Error("CheckBoundsFloat64(NaN, 1) = nil, want error")
+	}
+	if err := CheckBoundsFloat64("test", 0, math.NaN()); err == nil {
+		t.Error("CheckBoundsFloat64(0, NaN) = nil, want error")
+	}
+}
+
+func TestCheckBoundsNotEqual(t *testing.T) {
+	for _, tc := range []struct {
+		desc         string
+		lower, upper float64
+		wantErr      bool
+	}{
+		{"equal bounds fail", 1, 1, true},
+		{"signed zero vs positive zero fail", negZero, 0, true},
+		{"distinct bounds pass", 0, 1, false},
+		{"NaN lower fails", math.NaN(), 1, true},
+		{"NaN upper fails", 0, math.NaN(), true},
+		{"both NaN fails", math.NaN(), math.NaN(), true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := CheckBoundsNotEqual("test", tc.lower, tc.upper)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CheckBoundsNotEqual(%v, %v) returned err=%v, wantErr=%v", tc.lower, tc.upper, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckConfidenceLevelAndAlphaSignedZero(t *testing.T) {
+	if err := CheckConfidenceLevel("test", negZero); err != nil {
+		t.Errorf("CheckConfidenceLevel(-0.0) = %v, want nil: negative zero is within [0, 1]", err)
+	}
+	if err := CheckAlpha("test", negZero); err == nil {
+		t.Error("CheckAlpha(-0.0) = nil, want error: negative zero is not strictly greater than 0")
+	}
+	if err := CheckAlpha("test", math.NaN()); err == nil {
+		t.Error("CheckAlpha(NaN) = nil, want error")
+	}
+}
+
+func TestCheckRDPOrderAndZCDPRhoEdgeCases(t *testing.T) {
+	if err := CheckRDPOrder("test", math.NaN()); err == nil {
+		t.Error("CheckRDPOrder(NaN) = nil, want error")
+	}
+	if err := CheckRDPOrder("test", math.Nextafter(1, 0)); err == nil {
+		t.Error("CheckRDPOrder(one ULP below 1) = nil, want error: alpha must be strictly greater than 1")
+	}
+	if err := CheckZCDPRho("test", negZero); err != nil {
+		t.Errorf("CheckZCDPRho(-0.0) = %v, want nil: negative zero is nonnegative", err)
+	}
+	if err := CheckZCDPRho("test", math.SmallestNonzeroFloat64); err != nil {
+		t.Errorf("CheckZCDPRho(SmallestNonzeroFloat64) = %v, want nil", err)
+	}
+}
+
+func TestCheckGaussianSigmaSignedZero(t *testing.T) {
+	if err := CheckGaussianSigma("test", negZero, 1); err == nil {
+		t.Error("CheckGaussianSigma(-0.0, 1) = nil, want error: negative zero is not strictly positive")
+	}
+	if err := CheckGaussianSigma("test", 1, negZero); err == nil {
+		t.Error("CheckGaussianSigma(1, -0.0) = nil, want error: negative zero is not strictly positive")
+	}
+}
+
+// TestAdvancedCompositionEpsilonMonotonic checks that
+// advancedCompositionEpsilon is non-decreasing in epsilon for fixed k and
+// deltaPrime. This is what makes it sound for CheckComposedBudget to plug in
+// the largest ε_i seen so far across a heterogeneous sequence of steps: since
+// every actual ε_i is at most that maximum, the bound computed from the
+// maximum can only be greater than or equal to the bound that would have been
+// computed from any individual step's own ε_i, so the heterogeneous prefix is
+// never under-counted.
+func TestAdvancedCompositionEpsilonMonotonic(t *testing.T) {
+	const k = 5
+	const deltaPrime = 1e-6
+	epsilons := []float64{0.01, 0.05, 0.1, 0.5, 1, 2}
+	for i := 1; i < len(epsilons); i++ {
+		prev := advancedCompositionEpsilon(k, epsilons[i-1], deltaPrime)
+		cur := advancedCompositionEpsilon(k, epsilons[i], deltaPrime)
+		if cur < prev {
+			t.Errorf("advancedCompositionEpsilon(%d, %v, %v) = %v < advancedCompositionEpsilon(%d, %v, %v) = %v, want non-decreasing in epsilon",
+				k, epsilons[i], deltaPrime, cur, k, epsilons[i-1], deltaPrime, prev)
+		}
+	}
+}
+
+// TestCheckComposedBudgetHeterogeneousConservative checks that, for a mixed
+// sequence of step epsilons, CheckComposedBudget's advanced-composition bound
+// (computed from the running maximum ε_i) is never smaller than the bound
+// that substituting any single step's own, possibly smaller, ε into the same
+// formula would have produced. That is the property that makes using the
+// running maximum conservative rather than an under-count. The loop below
+// repeats CheckComposedBudget's per-prefix computation rather than calling it.
+func TestCheckComposedBudgetHeterogeneousConservative(t *testing.T) {
+	steps := []Step{
+		{Epsilon: 0.1, Delta: 1e-8, Mechanism: "laplace"},
+		{Epsilon: 0.5, Delta: 1e-8, Mechanism: "gaussian"},
+		{Epsilon: 0.2, Delta: 1e-8, Mechanism: "laplace"},
+	}
+	const totalDelta = 1e-6
+
+	var seqDelta, maxEpsilon float64
+	for i, step := range steps {
+		seqDelta += step.Delta
+		if step.Epsilon > maxEpsilon {
+			maxEpsilon = step.Epsilon
+		}
+		deltaPrime := totalDelta - seqDelta
+		boundFromMax := advancedCompositionEpsilon(i+1, maxEpsilon, deltaPrime)
+		boundFromStep := advancedCompositionEpsilon(i+1, step.Epsilon, deltaPrime)
+		if boundFromMax < boundFromStep {
+			t.Errorf("step %d: bound using running max epsilon (%v) = %v is smaller than bound using this step's own epsilon (%v) = %v, want >=",
+				i, maxEpsilon, boundFromMax, step.Epsilon, boundFromStep)
+		}
+	}
+}
+
+// drainWarnings empties the Warnings channel so tests don't observe
+// warnings published by whatever ran before them.
+func drainWarnings() {
+	for {
+		select {
+		case <-Warnings:
+		default:
+			return
+		}
+	}
+}
+
+// TestEmitWarningPublishesToWarningsChannel checks that emitWarning
+// publishes to Warnings regardless of SuppressGlogWarnings; suppression
+// only affects the warnf fallback (see TestSuppressGlogWarningsSkipsWarnf),
+// not delivery to Warnings itself.
+func TestEmitWarningPublishesToWarningsChannel(t *testing.T) {
+	drainWarnings()
+	SuppressGlogWarnings = true
+	defer func() { SuppressGlogWarnings = false }()
+
+	if err := CheckBoundsInt64("test", 5, 5); err != nil {
+		t.Fatalf("CheckBoundsInt64(5, 5) = %v, want nil (equal bounds is a warning, not an error)", err)
+	}
+	select {
+	case w := <-Warnings:
+		if w.Label != "test" {
+			t.Errorf("Warnings received label %q, want %q", w.Label, "test")
+		}
+	default:
+		t.Error("Warnings channel was empty, want the equal-bounds warning to have been published")
+	}
+}
+
+// TestSuppressGlogWarningsSkipsWarnf checks the actual suppression behavior:
+// with SuppressGlogWarnings set, emitWarning must not invoke warnf at all.
+// It does so by swapping warnf for a recorder for the duration of the test,
+// which also exercises the real code path (unlike asserting on Warnings
+// alone, which passes even if suppression is completely broken).
+func TestSuppressGlogWarningsSkipsWarnf(t *testing.T) {
+	drainWarnings()
+	origWarnf := warnf
+	defer func() { warnf = origWarnf }()
+
+	var calls []string
+	warnf = func(format string, args ...any) {
+		calls = append(calls, fmt.Sprintf(format, args...))
+	}
+
+	SuppressGlogWarnings = true
+	if err := CheckBoundsInt64("suppressed", 5, 5); err != nil {
+		t.Fatalf("CheckBoundsInt64(5, 5) = %v, want nil", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("warnf was called %v while SuppressGlogWarnings was true, want no calls", calls)
+	}
+
+	SuppressGlogWarnings = false
+	if err := CheckBoundsInt64("not-suppressed", 5, 5); err != nil {
+		t.Fatalf("CheckBoundsInt64(5, 5) = %v, want nil", err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("warnf was called %d times while SuppressGlogWarnings was false, want 1", len(calls))
+	}
+	if want := "not-suppressed: Lower bound is equal to upper bound: all added elements will be clamped to 5"; calls[0] != want {
+		t.Errorf("warnf called with %q, want %q", calls[0], want)
+	}
+}
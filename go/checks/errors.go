@@ -0,0 +1,189 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package checks
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorKind categorizes why a ValidationError was raised, so callers can
+// programmatically react to e.g. "epsilon too small" differently from
+// "bounds inverted" instead of parsing error text.
+type ErrorKind int
+
+const (
+	// KindNaN means the value was NaN.
+	KindNaN ErrorKind = iota
+	// KindInf means the value was +∞ or -∞ where only finite values are allowed.
+	KindInf
+	// KindNonPositive means the value was required to be strictly positive but wasn't.
+	KindNonPositive
+	// KindOutOfRange means the value fell outside an allowed interval (other than a simple sign requirement).
+	KindOutOfRange
+	// KindOverflowRisk means the value would overflow a downstream computation (e.g. sensitivity arithmetic).
+	KindOverflowRisk
+	// KindBoundsInverted means a lower bound was greater than its paired upper bound.
+	KindBoundsInverted
+)
+
+// String returns a short machine-stable name for the ErrorKind, used in error messages.
+func (k ErrorKind) String() string {
+	switch k {
+	case KindNaN:
+		return "NaN"
+	case KindInf:
+		return "Inf"
+	case KindNonPositive:
+		return "NonPositive"
+	case KindOutOfRange:
+		return "OutOfRange"
+	case KindOverflowRisk:
+		return "OverflowRisk"
+	case KindBoundsInverted:
+		return "BoundsInverted"
+	default:
+		return "Unknown"
+	}
+}
+
+// ValidationError is returned by Check* functions to report a single invalid
+// parameter in a form that can be inspected programmatically, rather than
+// only as error text.
+type ValidationError struct {
+	// Param is the name of the invalid parameter, e.g. "Epsilon" or "lower".
+	Param string
+	// Label is the caller-supplied label identifying the mechanism or call site being checked.
+	Label string
+	// Kind categorizes the failure.
+	Kind ErrorKind
+	// Value is the offending value, e.g. a float64 or int64.
+	Value any
+	// Constraint is a human-readable description of what was required.
+	Constraint string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s is %v, %s", e.Label, e.Param, e.Value, e.Constraint)
+}
+
+// Is reports whether target is a *ValidationError with the same Kind, so
+// callers can write `errors.Is(err, &ValidationError{Kind: checks.KindNaN})`.
+func (e *ValidationError) Is(target error) bool {
+	t, ok := target.(*ValidationError)
+	if !ok {
+		return false
+	}
+	return e.Kind == t.Kind
+}
+
+// ValidationErrors collects every problem found by MultiCheck, so a caller
+// can see every invalid field of a spec at once instead of only the first.
+type ValidationErrors []*ValidationError
+
+// Error implements the error interface.
+func (es ValidationErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap implements the Go 1.20 multi-error interface so errors.Is/As see
+// every wrapped ValidationError.
+func (es ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(es))
+	for i, e := range es {
+		errs[i] = e
+	}
+	return errs
+}
+
+// MultiCheck runs every check and accumulates their failures, so the caller
+// learns about every invalid field of a spec at once instead of stopping at
+// the first. It returns nil if every check passed, the failure's
+// *ValidationError if exactly one failed, or a ValidationErrors if more than
+// one failed. A failure that wraps a *ValidationError contributes that inner
+// *ValidationError (the wrapping text is dropped); any other error is
+// recorded as a KindOutOfRange ValidationError carrying its message.
+func MultiCheck(checks ...func() error) error {
+	var errs ValidationErrors
+	for _, check := range checks {
+		err := check()
+		if err == nil {
+			continue
+		}
+		var ve *ValidationError
+		if errors.As(err, &ve) {
+			errs = append(errs, ve)
+			continue
+		}
+		errs = append(errs, &ValidationError{Kind: KindOutOfRange, Constraint: err.Error()})
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errs
+	}
+}
+
+// ValidationWarning reports a non-fatal validation concern, such as a lower
+// bound equal to its upper bound, that doesn't rise to the level of an error.
+type ValidationWarning struct {
+	Param   string
+	Label   string
+	Message string
+}
+
+// warningsBufferSize bounds how many warnings can queue up on Warnings
+// before new ones are dropped rather than blocking the caller.
+const warningsBufferSize = 16
+
+// Warnings is the channel every Check* publishes ValidationWarnings to.
+// Hosts that want warnings-as-errors or warnings routed to structured
+// logging can drain this channel themselves; nobody is required to read it,
+// since emitWarning also logs via warnf (see SuppressGlogWarnings) and drops
+// warnings on the floor rather than blocking if the channel is full.
+var Warnings = make(chan ValidationWarning, warningsBufferSize)
+
+// SuppressGlogWarnings disables the warnf fallback in emitWarning at
+// runtime. Hosts that drain Warnings themselves and don't want warnings in
+// their logs can set this to true; it defaults to false so existing callers
+// that rely on warnings showing up in their logs see no change in behavior.
+// Hosts that want to drop the glog dependency itself, at compile time rather
+// than just its output, should build with the noglog tag (see
+// errors_glog.go / errors_noglog.go) instead of, or in addition to, this.
+var SuppressGlogWarnings bool
+
+// emitWarning records a ValidationWarning via warnf, unless SuppressGlogWarnings
+// is set, for backwards compatibility with hosts that don't read Warnings,
+// and publishes it to Warnings on a best-effort, non-blocking basis.
+func emitWarning(w ValidationWarning) {
+	if !SuppressGlogWarnings {
+		warnf("%s: %s", w.Label, w.Message)
+	}
+	select {
+	case Warnings <- w:
+	default:
+	}
+}
@@ -0,0 +1,25 @@
+//go:build noglog
+
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package checks
+
+// warnf is a no-op build of emitWarning's fallback logger, selected by the
+// noglog build tag in place of errors_glog.go so hosts that want to avoid
+// the glog dependency entirely can exclude it at compile time. Such hosts
+// must drain Warnings themselves, since this build never logs anything.
+var warnf = func(format string, args ...any) {}
@@ -0,0 +1,132 @@
+//
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package checks
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMultiCheck(t *testing.T) {
+	ok := func() error { return nil }
+	fail := func(kind ErrorKind) func() error {
+		return func() error { return &ValidationError{Param: "p", Kind: kind, Constraint: "c"} }
+	}
+
+	t.Run("0 failures returns nil", func(t *testing.T) {
+		if err := MultiCheck(ok, ok, ok); err != nil {
+			t.Errorf("MultiCheck(ok, ok, ok) = %v, want nil", err)
+		}
+	})
+
+	t.Run("1 failure returns the bare ValidationError", func(t *testing.T) {
+		err := MultiCheck(ok, fail(KindNaN), ok)
+		if err == nil {
+			t.Fatal("MultiCheck(ok, fail, ok) = nil, want an error")
+		}
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			t.Fatalf("MultiCheck(ok, fail, ok) = %v (%T), want a *ValidationError", err, err)
+		}
+		if _, ok := err.(ValidationErrors); ok {
+			t.Errorf("MultiCheck returned a ValidationErrors for a single failure, want the bare *ValidationError")
+		}
+		if ve.Kind != KindNaN {
+			t.Errorf("MultiCheck returned ValidationError with Kind %v, want %v", ve.Kind, KindNaN)
+		}
+	})
+
+	t.Run("N failures returns ValidationErrors in order", func(t *testing.T) {
+		err := MultiCheck(fail(KindNaN), ok, fail(KindInf), fail(KindOutOfRange))
+		errs, ok := err.(ValidationErrors)
+		if !ok {
+			t.Fatalf("MultiCheck returned %T, want ValidationErrors", err)
+		}
+		if len(errs) != 3 {
+			t.Fatalf("MultiCheck returned %d errors, want 3", len(errs))
+		}
+		wantKinds := []ErrorKind{KindNaN, KindInf, KindOutOfRange}
+		for i, want := range wantKinds {
+			if errs[i].Kind != want {
+				t.Errorf("errs[%d].Kind = %v, want %v", i, errs[i].Kind, want)
+			}
+		}
+	})
+
+	t.Run("non-ValidationError failures are wrapped as KindOutOfRange", func(t *testing.T) {
+		plain := func() error { return errors.New("not a ValidationError") }
+		err := MultiCheck(plain, fail(KindNaN))
+		errs, ok := err.(ValidationErrors)
+		if !ok {
+			t.Fatalf("MultiCheck returned %T, want ValidationErrors", err)
+		}
+		if len(errs) != 2 || errs[0].Kind != KindOutOfRange {
+			t.Errorf("MultiCheck(plain, fail) = %v, want first error wrapped as KindOutOfRange", errs)
+		}
+	})
+}
+
+func TestValidationErrorIsComparesOnKind(t *testing.T) {
+	a := &ValidationError{Label: "a", Param: "Epsilon", Kind: KindNaN, Constraint: "cannot be NaN"}
+	sameKind := &ValidationError{Label: "b", Param: "Delta", Kind: KindNaN, Constraint: "different message"}
+	differentKind := &ValidationError{Label: "a", Param: "Epsilon", Kind: KindInf, Constraint: "cannot be NaN"}
+
+	if !errors.Is(a, sameKind) {
+		t.Error("errors.Is(a, sameKind) = false, want true: (*ValidationError).Is should compare only on Kind")
+	}
+	if errors.Is(a, differentKind) {
+		t.Error("errors.Is(a, differentKind) = true, want false: Kind differs")
+	}
+}
+
+func TestErrorsAsThroughWrappedValidationError(t *testing.T) {
+	t.Run("CheckComposedBudget wraps a ValidationError for an invalid total epsilon", func(t *testing.T) {
+		err := CheckComposedBudget("test", nil, -1, 0.1)
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			t.Fatalf("CheckComposedBudget returned %v, want an error wrapping *ValidationError", err)
+		}
+		if ve.Kind != KindOutOfRange && ve.Kind != KindNonPositive {
+			t.Errorf("unwrapped ValidationError has Kind %v, want a sign-related kind for a negative epsilon", ve.Kind)
+		}
+		if !errors.Is(err, &ValidationError{Kind: ve.Kind}) {
+			t.Error("errors.Is(err, &ValidationError{Kind: ve.Kind}) = false, want true through the %w-wrapped chain")
+		}
+	})
+
+	t.Run("CheckRDPToApproxDP wraps a ValidationError for an invalid target delta", func(t *testing.T) {
+		err := CheckRDPToApproxDP("test", 2, 0.1, -1, 1)
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			t.Fatalf("CheckRDPToApproxDP returned %v, want an error wrapping *ValidationError", err)
+		}
+		if !errors.Is(err, &ValidationError{Kind: ve.Kind}) {
+			t.Error("errors.Is(err, &ValidationError{Kind: ve.Kind}) = false, want true through the %w-wrapped chain")
+		}
+	})
+
+	t.Run("an unwrapped ValidationError also satisfies errors.As", func(t *testing.T) {
+		err := CheckEpsilon("test", -1)
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			t.Fatalf("CheckEpsilon returned %v, want a *ValidationError", err)
+		}
+		if ve.Kind != KindOutOfRange {
+			t.Errorf("CheckEpsilon(-1) Kind = %v, want %v", ve.Kind, KindOutOfRange)
+		}
+	})
+}